@@ -0,0 +1,54 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import "fmt"
+
+// ColCollection is an ordered collection of Columns, indexed by tag. Column
+// order is significant: it is the order columns are presented in a
+// generated Schema.
+type ColCollection struct {
+	Cols []Column
+	// TagToIdx maps a Column's tag to its position in Cols.
+	TagToIdx map[uint64]int
+}
+
+// NewColCollection creates a ColCollection from the given columns, in the
+// order provided. It is an error for two columns to share a tag.
+func NewColCollection(cols ...Column) (*ColCollection, error) {
+	tagToIdx := make(map[uint64]int, len(cols))
+	for i, col := range cols {
+		if _, ok := tagToIdx[col.Tag]; ok {
+			return nil, fmt.Errorf("duplicate tag %d in column collection", col.Tag)
+		}
+		tagToIdx[col.Tag] = i
+	}
+
+	return &ColCollection{Cols: cols, TagToIdx: tagToIdx}, nil
+}
+
+// GetByTag returns the Column with the given tag, if present.
+func (cc *ColCollection) GetByTag(tag uint64) (Column, bool) {
+	idx, ok := cc.TagToIdx[tag]
+	if !ok {
+		return Column{}, false
+	}
+	return cc.Cols[idx], true
+}
+
+// Size returns the number of columns in the collection.
+func (cc *ColCollection) Size() int {
+	return len(cc.Cols)
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// ColConstraint is a constraint on a Column's values, such as NOT NULL.
+type ColConstraint interface {
+	// Name is the constraint's type name, e.g. "not_null".
+	Name() string
+}
+
+// Column describes a single column of a Schema: its name, its tag (the
+// stable identifier used to track the column across renames), the NomsKind
+// used to store its values, whether it is part of the primary key, its
+// TypeInfo, and any constraints placed on it.
+type Column struct {
+	Name        string
+	Tag         uint64
+	Kind        types.NomsKind
+	IsPartOfPK  bool
+	TypeInfo    typeinfo.TypeInfo
+	Constraints []ColConstraint
+}
+
+// NewColumn creates a Column with the given name, tag, kind and PK-ness.
+func NewColumn(name string, tag uint64, kind types.NomsKind, isPartOfPK bool, ti typeinfo.TypeInfo, constraints ...ColConstraint) Column {
+	return Column{Name: name, Tag: tag, Kind: kind, IsPartOfPK: isPartOfPK, TypeInfo: ti, Constraints: constraints}
+}
+
+// NotNullConstraint marks a Column as disallowing NULL values.
+type NotNullConstraint struct{}
+
+// Name implements ColConstraint.
+func (NotNullConstraint) Name() string {
+	return "not_null"
+}
+
+// ConstraintFromName returns the ColConstraint with the given Name(), used
+// when decoding a constraint that was serialized as its name.
+func ConstraintFromName(name string) (ColConstraint, bool) {
+	switch name {
+	case (NotNullConstraint{}).Name():
+		return NotNullConstraint{}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// Schema describes the columns that make up a table, in display order.
+type Schema interface {
+	// GetAllCols returns the ColCollection backing this Schema.
+	GetAllCols() *ColCollection
+}
+
+type schemaImpl struct {
+	allCols *ColCollection
+}
+
+func (s schemaImpl) GetAllCols() *ColCollection {
+	return s.allCols
+}
+
+// SchemaFromCols builds a Schema whose columns are exactly those in cc, in
+// the order they appear there.
+func SchemaFromCols(cc *ColCollection) Schema {
+	return schemaImpl{allCols: cc}
+}
+
+// SchemasAreEqual returns true if s1 and s2 describe the same columns, in
+// the same order, with the same names, tags and types.
+func SchemasAreEqual(s1, s2 Schema) (bool, error) {
+	cc1, cc2 := s1.GetAllCols(), s2.GetAllCols()
+
+	if cc1.Size() != cc2.Size() {
+		return false, nil
+	}
+
+	for i, col1 := range cc1.Cols {
+		col2 := cc2.Cols[i]
+		if col1.Name != col2.Name || col1.Tag != col2.Tag || col1.Kind != col2.Kind || col1.IsPartOfPK != col2.IsPartOfPK {
+			return false, nil
+		}
+		if (col1.TypeInfo == nil) != (col2.TypeInfo == nil) {
+			return false, nil
+		}
+		if col1.TypeInfo != nil && !col1.TypeInfo.Equals(col2.TypeInfo) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
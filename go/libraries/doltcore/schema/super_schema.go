@@ -0,0 +1,255 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/typeinfo"
+)
+
+// SuperSchema is the union of every Schema a table has had over its
+// history. Each tag seen across the input schemas contributes exactly one
+// entry to allCols, keyed by the order the tag was first introduced. Every
+// name a tag has ever been known by is kept in tagNames so that
+// GenerateSchema can pick the most appropriate current name.
+type SuperSchema struct {
+	allCols  *ColCollection
+	tagNames map[uint64][]string
+	// promotions records, for tags built with NewSuperSchemaWithPromotion,
+	// every narrower TypeInfo a tag was promoted from, most-recently-seen
+	// first. It has no entries for SuperSchemas built with NewSuperSchema.
+	promotions map[uint64][]typeinfo.TypeInfo
+	// renameGraph tracks, per tag, every rename observed as schemas were
+	// added, in the order they were observed. See RenameGraph.
+	renameGraph map[uint64][]RenameEdge
+	// nameTimeline records, per tag, the name the tag had after each
+	// schema was added, used by CanonicalName.
+	nameTimeline map[uint64][]nameAtSchema
+}
+
+type nameAtSchema struct {
+	schemaIdx int
+	name      string
+}
+
+// NewSuperSchema builds a SuperSchema from schemas, in the order given.
+// Schemas added later take priority when choosing the display name for a
+// tag. It is an error for two schemas to define the same tag with
+// different Kind, TypeInfo or primary-key-ness.
+func NewSuperSchema(schemas ...Schema) (*SuperSchema, error) {
+	ss := newEmptySuperSchema()
+
+	for i, sch := range schemas {
+		if err := ss.addSchema(sch, nil, i); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, nil
+}
+
+func newEmptySuperSchema() *SuperSchema {
+	return &SuperSchema{
+		allCols:      &ColCollection{TagToIdx: map[uint64]int{}},
+		tagNames:     map[uint64][]string{},
+		promotions:   map[uint64][]typeinfo.TypeInfo{},
+		renameGraph:  map[uint64][]RenameEdge{},
+		nameTimeline: map[uint64][]nameAtSchema{},
+	}
+}
+
+// NewSuperSchemaWithPromotion is a variant of NewSuperSchema that, rather
+// than erroring when two schemas define a tag with different but
+// type-compatible TypeInfo, widens the tag to a type both definitions fit
+// in, as chosen by widenTypes. See typePromotionLattice for the promotion
+// rules.
+// Incompatible pairs (e.g. Blob vs Int) still produce the same error
+// NewSuperSchema would.
+func NewSuperSchemaWithPromotion(schemas ...Schema) (*SuperSchema, error) {
+	ss := newEmptySuperSchema()
+
+	for i, sch := range schemas {
+		if err := ss.addSchema(sch, widenCompatibleTypes, i); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, nil
+}
+
+// typeResolver reconciles the TypeInfo of a column already present in the
+// SuperSchema with the TypeInfo of a newly-seen definition of the same tag.
+// It returns the TypeInfo the tag should use going forward, or ok=false if
+// the two definitions cannot be reconciled.
+type typeResolver func(existing, incoming Column) (typeinfo.TypeInfo, bool)
+
+func (ss *SuperSchema) addSchema(sch Schema, resolve typeResolver, schemaIdx int) error {
+	for _, col := range sch.GetAllCols().Cols {
+		if err := ss.addColumn(col, resolve, schemaIdx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *SuperSchema) addColumn(col Column, resolve typeResolver, schemaIdx int) error {
+	existing, ok := ss.allCols.GetByTag(col.Tag)
+
+	if !ok {
+		stored := col
+		stored.Name = ""
+		ss.allCols.Cols = append(ss.allCols.Cols, stored)
+		ss.allCols.TagToIdx[col.Tag] = len(ss.allCols.Cols) - 1
+		ss.tagNames[col.Tag] = []string{col.Name}
+		ss.nameTimeline[col.Tag] = []nameAtSchema{{schemaIdx: schemaIdx, name: col.Name}}
+
+		return nil
+	}
+
+	sameDefinition := existing.Kind == col.Kind && existing.IsPartOfPK == col.IsPartOfPK && typeInfoEquals(existing.TypeInfo, col.TypeInfo)
+
+	if !sameDefinition {
+		var widened typeinfo.TypeInfo
+		var widenable bool
+		if resolve != nil {
+			widened, widenable = resolve(existing, col)
+		}
+
+		if !widenable || existing.IsPartOfPK != col.IsPartOfPK {
+			existingName := ss.tagNames[col.Tag][0]
+			return fmt.Errorf("tag collision for columns %s and %s, different definitions (tag: %d)", existingName, col.Name, col.Tag)
+		}
+
+		if !typeInfoEquals(existing.TypeInfo, widened) {
+			idx := ss.allCols.TagToIdx[col.Tag]
+			ss.allCols.Cols[idx].TypeInfo = widened
+			ss.allCols.Cols[idx].Kind = widened.NomsKind()
+			ss.promotions[col.Tag] = prependTypeInfo(ss.promotions[col.Tag], existing.TypeInfo)
+		}
+
+		if !typeInfoEquals(col.TypeInfo, widened) {
+			ss.promotions[col.Tag] = prependTypeInfo(ss.promotions[col.Tag], col.TypeInfo)
+		}
+	}
+
+	if resolve != nil {
+		idx := ss.allCols.TagToIdx[col.Tag]
+		ss.allCols.Cols[idx].Constraints = widenNullability(ss.allCols.Cols[idx].Constraints, col.Constraints)
+	}
+
+	currentName := ss.tagNames[col.Tag][0]
+	if currentName != col.Name {
+		ss.renameGraph[col.Tag] = append(ss.renameGraph[col.Tag], RenameEdge{From: currentName, To: col.Name, SourceSchemaID: uint64(schemaIdx)})
+	}
+	ss.nameTimeline[col.Tag] = append(ss.nameTimeline[col.Tag], nameAtSchema{schemaIdx: schemaIdx, name: col.Name})
+
+	ss.tagNames[col.Tag] = prependName(ss.tagNames[col.Tag], col.Name)
+
+	return nil
+}
+
+func typeInfoEquals(a, b typeinfo.TypeInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equals(b)
+}
+
+func prependName(names []string, name string) []string {
+	if len(names) > 0 && names[0] == name {
+		return names
+	}
+	return append([]string{name}, names...)
+}
+
+func prependTypeInfo(tis []typeinfo.TypeInfo, ti typeinfo.TypeInfo) []typeinfo.TypeInfo {
+	if len(tis) > 0 && typeInfoEquals(tis[0], ti) {
+		return tis
+	}
+	return append([]typeinfo.TypeInfo{ti}, tis...)
+}
+
+// GenerateSchema materializes a Schema from the SuperSchema: every tag in
+// allCols, in the order it was first introduced, named after the most
+// recently seen name for that tag. If two tags currently resolve to the
+// same name, or a tag's rename history contains a cycle, each affected
+// column is disambiguated as "name_tag" - the same fallback used for plain
+// name collisions.
+func (ss *SuperSchema) GenerateSchema() (Schema, error) {
+	cyclicTags := ss.tagsWithRenameCycles()
+
+	cols := make([]Column, len(ss.allCols.Cols))
+	nameUsers := map[string]int{}
+
+	for i, col := range ss.allCols.Cols {
+		name := ss.tagNames[col.Tag][0]
+		cols[i] = col
+		cols[i].Name = name
+		nameUsers[name]++
+	}
+
+	for i, col := range cols {
+		if nameUsers[col.Name] > 1 || cyclicTags[col.Tag] {
+			cols[i].Name = fmt.Sprintf("%s_%d", col.Name, col.Tag)
+		}
+	}
+
+	cc, err := NewColCollection(cols...)
+	if err != nil {
+		return nil, err
+	}
+
+	return SchemaFromCols(cc), nil
+}
+
+// Equals returns true if ss and other describe the same tags, with the
+// same current and historical names, and the same underlying types.
+func (ss *SuperSchema) Equals(other *SuperSchema) bool {
+	if other == nil {
+		return false
+	}
+	return reflect.DeepEqual(ss.tagNames, other.tagNames) && reflect.DeepEqual(ss.allCols.Cols, other.allCols.Cols)
+}
+
+// SuperSchemaUnion combines ss1 and ss2 into a new SuperSchema, with ss2's
+// names taking priority over ss1's for any tag shared between them. Tags
+// shared between ss1 and ss2 with type-compatible but differing TypeInfo
+// (as can happen when either input was built with
+// NewSuperSchemaWithPromotion) are widened rather than rejected, so unioning
+// two already-promoted SuperSchemas never double-widens a tag past what
+// either side already settled on.
+func SuperSchemaUnion(ss1, ss2 *SuperSchema) (*SuperSchema, error) {
+	gs1, err := ss1.GenerateSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	gs2, err := ss2.GenerateSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	ss := newEmptySuperSchema()
+
+	for i, gs := range []Schema{gs1, gs2} {
+		if err := ss.addSchema(gs, widenCompatibleTypes, i); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+
+	"github.com/liquidata-inc/dolt/go/store/types"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/typeinfo"
+)
+
+// typePromotionLattice holds the single-step widenings that are always
+// safe: every value representable in the "from" type is representable in
+// the "to" type without loss. NewSuperSchemaWithPromotion walks this
+// lattice (plus the any-numeric->Decimal and any-scalar->String rules
+// below) to reconcile two differing definitions of the same tag.
+var typePromotionLattice = map[typeinfo.Identifier]typeinfo.Identifier{
+	typeinfo.Int8TypeIdentifier:    typeinfo.Int16TypeIdentifier,
+	typeinfo.Int16TypeIdentifier:   typeinfo.Int32TypeIdentifier,
+	typeinfo.Int32TypeIdentifier:   typeinfo.Int64TypeIdentifier,
+	typeinfo.Uint8TypeIdentifier:   typeinfo.Int16TypeIdentifier,
+	typeinfo.Uint16TypeIdentifier:  typeinfo.Int32TypeIdentifier,
+	typeinfo.Uint32TypeIdentifier:  typeinfo.Int64TypeIdentifier,
+	typeinfo.Uint64TypeIdentifier:  typeinfo.DecimalTypeIdentifier,
+	typeinfo.Float32TypeIdentifier: typeinfo.Float64TypeIdentifier,
+}
+
+var numericTypeIdentifiers = map[typeinfo.Identifier]bool{
+	typeinfo.Int8TypeIdentifier:    true,
+	typeinfo.Int16TypeIdentifier:   true,
+	typeinfo.Int32TypeIdentifier:   true,
+	typeinfo.Int64TypeIdentifier:   true,
+	typeinfo.Uint8TypeIdentifier:   true,
+	typeinfo.Uint16TypeIdentifier:  true,
+	typeinfo.Uint32TypeIdentifier:  true,
+	typeinfo.Uint64TypeIdentifier:  true,
+	typeinfo.Float32TypeIdentifier: true,
+	typeinfo.Float64TypeIdentifier: true,
+	typeinfo.DecimalTypeIdentifier: true,
+}
+
+var scalarTypeIdentifiers = map[typeinfo.Identifier]bool{
+	typeinfo.BoolTypeIdentifier:      true,
+	typeinfo.TimestampTypeIdentifier: true,
+	typeinfo.DateTypeIdentifier:      true,
+	typeinfo.TimeTypeIdentifier:      true,
+	typeinfo.StringTypeIdentifier:    true,
+}
+
+func isNumericType(ti typeinfo.TypeInfo) bool {
+	return ti != nil && numericTypeIdentifiers[ti.Identifier()]
+}
+
+func isScalarType(ti typeinfo.TypeInfo) bool {
+	return ti != nil && (isNumericType(ti) || scalarTypeIdentifiers[ti.Identifier()])
+}
+
+// canPromoteChain returns true if from can reach to by following zero or
+// more steps of typePromotionLattice, e.g. Int8->Int16->Int32.
+func canPromoteChain(from, to typeinfo.TypeInfo) bool {
+	if from == nil || to == nil {
+		return false
+	}
+
+	id := from.Identifier()
+	for steps := 0; steps < len(typePromotionLattice)+1; steps++ {
+		if id == to.Identifier() {
+			return true
+		}
+		next, ok := typePromotionLattice[id]
+		if !ok {
+			return false
+		}
+		id = next
+	}
+
+	return false
+}
+
+// widenTypes returns a TypeInfo that both a and b can be represented as
+// without truncation, and whether such a type exists: whichever of a and b
+// the other reaches along typePromotionLattice, else Decimal if both are
+// numeric, else String if both are scalar. Two numerics on different lattice
+// chains (e.g. Int8 and Uint8) widen to Decimal, not to a shared lattice
+// successor such as Int16.
+func widenTypes(a, b typeinfo.TypeInfo) (typeinfo.TypeInfo, bool) {
+	if typeInfoEquals(a, b) {
+		return a, true
+	}
+	if canPromoteChain(a, b) {
+		return b, true
+	}
+	if canPromoteChain(b, a) {
+		return a, true
+	}
+	if isNumericType(a) && isNumericType(b) {
+		return typeinfo.DecimalType, true
+	}
+	if isScalarType(a) && isScalarType(b) {
+		return typeinfo.StringDefaultType, true
+	}
+	return nil, false
+}
+
+// widenCompatibleTypes is the typeResolver used by
+// NewSuperSchemaWithPromotion.
+func widenCompatibleTypes(existing, incoming Column) (typeinfo.TypeInfo, bool) {
+	return widenTypes(existing.TypeInfo, incoming.TypeInfo)
+}
+
+// hasNotNull returns true if cs contains a NotNullConstraint.
+func hasNotNull(cs []ColConstraint) bool {
+	for _, c := range cs {
+		if _, ok := c.(NotNullConstraint); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// widenNullability merges the NOT NULL-ness of existing and incoming: the
+// widened column is NOT NULL only if every definition seen for the tag so
+// far required it, since a historical row written under a nullable
+// definition may in fact contain a NULL for this tag. Constraints other
+// than NotNullConstraint are passed through from existing unchanged.
+func widenNullability(existing, incoming []ColConstraint) []ColConstraint {
+	notNull := hasNotNull(existing) && hasNotNull(incoming)
+
+	widened := make([]ColConstraint, 0, len(existing))
+	for _, c := range existing {
+		if _, ok := c.(NotNullConstraint); ok {
+			continue
+		}
+		widened = append(widened, c)
+	}
+
+	if notNull {
+		widened = append(widened, NotNullConstraint{})
+	}
+
+	if len(widened) == 0 {
+		return nil
+	}
+
+	return widened
+}
+
+// ConvertRow reports whether a value stored under oldType can be carried
+// forward as the type tag currently resolves to in ss, returning val
+// unchanged if oldType already matches the current type. Callers
+// materializing historical rows through a promoted SuperSchema use this to
+// check whether an old value needs re-encoding before being handed to
+// anything that expects the SuperSchema's GenerateSchema() types.
+//
+// It does not perform that re-encoding itself: the types package here has no
+// concrete Value implementations to re-encode bytes against, so rather than
+// silently relabeling oldType-encoded bytes as col.TypeInfo, ConvertRow
+// errors on any oldType that actually needs upcasting. A real Value
+// implementation would re-encode val under col.TypeInfo in that case instead
+// of erroring.
+func (ss *SuperSchema) ConvertRow(tag uint64, val types.Value, oldType typeinfo.TypeInfo) (types.Value, error) {
+	col, ok := ss.allCols.GetByTag(tag)
+	if !ok {
+		return nil, fmt.Errorf("ConvertRow: no column for tag %d", tag)
+	}
+
+	if typeInfoEquals(col.TypeInfo, oldType) {
+		return val, nil
+	}
+
+	if _, ok := widenTypes(oldType, col.TypeInfo); !ok {
+		return nil, fmt.Errorf("ConvertRow: %s cannot be converted to %s for tag %d", oldType, col.TypeInfo, tag)
+	}
+
+	return nil, fmt.Errorf("ConvertRow: %s requires upcasting to %s for tag %d, but no value re-encoding is implemented", oldType, col.TypeInfo, tag)
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func numCol(name string, tag uint64, isPK bool, ti typeinfo.TypeInfo) Column {
+	return Column{name, tag, ti.NomsKind(), isPK, ti, nil}
+}
+
+type PromotionTest struct {
+	// Name of the test
+	Name string
+	// FromType is the narrower type introduced first.
+	FromType typeinfo.TypeInfo
+	// ToType is the type introduced second.
+	ToType typeinfo.TypeInfo
+	// ExpectedWidenedType is the type tag 1 should resolve to.
+	ExpectedWidenedType typeinfo.TypeInfo
+}
+
+var PromotionTests = []PromotionTest{
+	{"Int8 promotes to Int16", typeinfo.Int8Type, typeinfo.Int16Type, typeinfo.Int16Type},
+	{"Int16 promotes to Int32", typeinfo.Int16Type, typeinfo.Int32Type, typeinfo.Int32Type},
+	{"Int32 promotes to Int64", typeinfo.Int32Type, typeinfo.Int64Type, typeinfo.Int64Type},
+	{"Uint8 promotes to Int16", typeinfo.Uint8Type, typeinfo.Int16Type, typeinfo.Int16Type},
+	{"Uint16 promotes to Int32", typeinfo.Uint16Type, typeinfo.Int32Type, typeinfo.Int32Type},
+	{"Uint32 promotes to Int64", typeinfo.Uint32Type, typeinfo.Int64Type, typeinfo.Int64Type},
+	{"Uint64 promotes to Decimal", typeinfo.Uint64Type, typeinfo.DecimalType, typeinfo.DecimalType},
+	{"Float32 promotes to Float64", typeinfo.Float32Type, typeinfo.Float64Type, typeinfo.Float64Type},
+	{"unrelated numerics join at Decimal", typeinfo.Int32Type, typeinfo.Float64Type, typeinfo.DecimalType},
+	{"any scalar joins at String", typeinfo.BoolDefaultType, typeinfo.StringDefaultType, typeinfo.StringDefaultType},
+	{"Timestamp widens to String", typeinfo.TimestampType, typeinfo.StringDefaultType, typeinfo.StringDefaultType},
+	{"Date widens to String", typeinfo.DateType, typeinfo.StringDefaultType, typeinfo.StringDefaultType},
+	{"Time widens to String", typeinfo.TimeType, typeinfo.StringDefaultType, typeinfo.StringDefaultType},
+	{"reversed introduction order still widens, never truncates", typeinfo.Int64Type, typeinfo.Int32Type, typeinfo.Int64Type},
+}
+
+func TestSuperSchemaPromotion(t *testing.T) {
+	for _, test := range PromotionTests {
+		t.Run(test.Name, func(t *testing.T) {
+			fromSch := mustSchema([]Column{numCol("x", 1, false, test.FromType)})
+			toSch := mustSchema([]Column{numCol("x", 1, false, test.ToType)})
+
+			ss, err := NewSuperSchemaWithPromotion(fromSch, toSch)
+			require.NoError(t, err)
+
+			gs, err := ss.GenerateSchema()
+			require.NoError(t, err)
+
+			col, ok := gs.GetAllCols().GetByTag(1)
+			require.True(t, ok)
+			assert.True(t, test.ExpectedWidenedType.Equals(col.TypeInfo), "expected %s, got %s", test.ExpectedWidenedType, col.TypeInfo)
+		})
+	}
+
+	t.Run("incompatible types still error", func(t *testing.T) {
+		blobSch := mustSchema([]Column{numCol("x", 1, false, typeinfo.BlobDefaultType)})
+		intSch := mustSchema([]Column{numCol("y", 1, false, typeinfo.Int32Type)})
+
+		_, err := NewSuperSchemaWithPromotion(blobSch, intSch)
+		assert.Error(t, err, "tag collision for columns x and y, different definitions (tag: 1)")
+	})
+
+	t.Run("nullability widens to the least restrictive definition, regardless of order", func(t *testing.T) {
+		notNullSch := mustSchema([]Column{{"x", 1, types.IntKind, false, typeinfo.Int32Type, []ColConstraint{NotNullConstraint{}}}})
+		nullableSch := mustSchema([]Column{numCol("x", 1, false, typeinfo.Int32Type)})
+
+		ss, err := NewSuperSchemaWithPromotion(notNullSch, nullableSch)
+		require.NoError(t, err)
+		gs, err := ss.GenerateSchema()
+		require.NoError(t, err)
+		col, ok := gs.GetAllCols().GetByTag(1)
+		require.True(t, ok)
+		assert.False(t, hasNotNull(col.Constraints), "a historical nullable definition means NOT NULL can't be enforced across the SuperSchema")
+
+		// reversing which schema is added first must not change the result
+		ss, err = NewSuperSchemaWithPromotion(nullableSch, notNullSch)
+		require.NoError(t, err)
+		gs, err = ss.GenerateSchema()
+		require.NoError(t, err)
+		col, ok = gs.GetAllCols().GetByTag(1)
+		require.True(t, ok)
+		assert.False(t, hasNotNull(col.Constraints))
+	})
+
+	t.Run("nullability stays NOT NULL when every definition requires it", func(t *testing.T) {
+		notNullSch := mustSchema([]Column{{"x", 1, types.IntKind, false, typeinfo.Int32Type, []ColConstraint{NotNullConstraint{}}}})
+		alsoNotNullSch := mustSchema([]Column{{"x", 1, types.IntKind, false, typeinfo.Int64Type, []ColConstraint{NotNullConstraint{}}}})
+
+		ss, err := NewSuperSchemaWithPromotion(notNullSch, alsoNotNullSch)
+		require.NoError(t, err)
+		gs, err := ss.GenerateSchema()
+		require.NoError(t, err)
+		col, ok := gs.GetAllCols().GetByTag(1)
+		require.True(t, ok)
+		assert.True(t, hasNotNull(col.Constraints))
+	})
+
+	t.Run("SuperSchemaUnion composes two already-promoted SuperSchemas without double-widening", func(t *testing.T) {
+		ssA, err := NewSuperSchemaWithPromotion(
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int8Type)}),
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int32Type)}),
+		)
+		require.NoError(t, err)
+
+		ssB, err := NewSuperSchemaWithPromotion(
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int16Type)}),
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int64Type)}),
+		)
+		require.NoError(t, err)
+
+		union, err := SuperSchemaUnion(ssA, ssB)
+		require.NoError(t, err)
+
+		gs, err := union.GenerateSchema()
+		require.NoError(t, err)
+
+		col, ok := gs.GetAllCols().GetByTag(1)
+		require.True(t, ok)
+		assert.True(t, typeinfo.Int64Type.Equals(col.TypeInfo))
+	})
+
+	t.Run("ConvertRow is a no-op once the value's type matches the widened type", func(t *testing.T) {
+		ss, err := NewSuperSchemaWithPromotion(
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int32Type)}),
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int64Type)}),
+		)
+		require.NoError(t, err)
+
+		v, err := ss.ConvertRow(1, nil, typeinfo.Int64Type)
+		require.NoError(t, err)
+		assert.Nil(t, v)
+
+		_, err = ss.ConvertRow(1, nil, typeinfo.BlobDefaultType)
+		assert.Error(t, err)
+	})
+
+	t.Run("ConvertRow errors rather than silently relabeling a value that actually needs upcasting", func(t *testing.T) {
+		ss, err := NewSuperSchemaWithPromotion(
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int8Type)}),
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int64Type)}),
+		)
+		require.NoError(t, err)
+
+		_, err = ss.ConvertRow(1, nil, typeinfo.Int8Type)
+		assert.Error(t, err, "Int8 bytes cannot be silently relabeled as Int64 without a real re-encode")
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// RenameEdge is one observed rename of a tag: its name changed from From to
+// To when the schema at index SourceSchemaID (0-based, in the order passed
+// to NewSuperSchema / NewSuperSchemaWithPromotion / SuperSchemaUnion) was
+// added to the SuperSchema.
+type RenameEdge struct {
+	From           string
+	To             string
+	SourceSchemaID uint64
+}
+
+// RenameHistory returns every rename observed for tag, in the order the
+// schemas that introduced them were added. It returns nil if tag has never
+// been renamed (including if it is unknown to the SuperSchema).
+func (ss *SuperSchema) RenameHistory(tag uint64) []RenameEdge {
+	return ss.renameGraph[tag]
+}
+
+// CanonicalName returns the name tag resolved to as of the schema at index
+// atSchemaIdx (0-based, inclusive). It returns "" if tag had not yet been
+// introduced by that point.
+func (ss *SuperSchema) CanonicalName(tag uint64, atSchemaIdx int) string {
+	name := ""
+	for _, entry := range ss.nameTimeline[tag] {
+		if entry.schemaIdx > atSchemaIdx {
+			break
+		}
+		name = entry.name
+	}
+	return name
+}
+
+// DetectRenameCycles walks the rename graph of every tag and returns every
+// disjoint cycle found, e.g. a->b->a. Because the same physical name can
+// legitimately reappear on two different tags, cycles are detected
+// per-tag: a name reused across tags is never reported as a cycle.
+func (ss *SuperSchema) DetectRenameCycles() [][]RenameEdge {
+	var cycles [][]RenameEdge
+	for tag := range ss.renameGraph {
+		cycles = append(cycles, detectCyclesInTagGraph(ss.renameGraph[tag])...)
+	}
+	return cycles
+}
+
+func (ss *SuperSchema) tagsWithRenameCycles() map[uint64]bool {
+	cyclic := map[uint64]bool{}
+	for tag, edges := range ss.renameGraph {
+		if len(detectCyclesInTagGraph(edges)) > 0 {
+			cyclic[tag] = true
+		}
+	}
+	return cyclic
+}
+
+const (
+	white = 0
+	gray  = 1
+	black = 2
+)
+
+// detectCyclesInTagGraph runs an iterative DFS with three-color marking over
+// the rename graph of a single tag (nodes are names, edges are renames).
+// Every time a back edge to a gray node is found, the cycle is reconstructed
+// from the current recursion stack and recorded; the walk continues rather
+// than stopping at the first cycle, so that all disjoint cycles in the
+// graph are returned.
+func detectCyclesInTagGraph(edges []RenameEdge) [][]RenameEdge {
+	adj := map[string][]RenameEdge{}
+	nodesInOrder := []string{}
+	seenNode := map[string]bool{}
+	for _, e := range edges {
+		adj[e.From] = append(adj[e.From], e)
+		for _, n := range []string{e.From, e.To} {
+			if !seenNode[n] {
+				seenNode[n] = true
+				nodesInOrder = append(nodesInOrder, n)
+			}
+		}
+	}
+
+	color := map[string]int{}
+	var cycles [][]RenameEdge
+
+	type frame struct {
+		node    string
+		edgeIdx int
+	}
+
+	for _, start := range nodesInOrder {
+		if color[start] != white {
+			continue
+		}
+
+		stack := []frame{{node: start}}
+		color[start] = gray
+		path := []RenameEdge{}
+
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+
+			if top.edgeIdx >= len(adj[top.node]) {
+				color[top.node] = black
+				if len(path) > 0 {
+					path = path[:len(path)-1]
+				}
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			edge := adj[top.node][top.edgeIdx]
+			top.edgeIdx++
+
+			switch color[edge.To] {
+			case white:
+				color[edge.To] = gray
+				path = append(path, edge)
+				stack = append(stack, frame{node: edge.To})
+			case gray:
+				cycle := []RenameEdge{edge}
+				for i := len(path) - 1; i >= 0; i-- {
+					cycle = append(cycle, path[i])
+					if path[i].From == edge.To {
+						break
+					}
+				}
+				reverseRenameEdges(cycle)
+				cycles = append(cycles, cycle)
+			case black:
+				// cross edge into an already-fully-explored node; not part
+				// of a cycle containing any node still on the stack.
+			}
+		}
+	}
+
+	return cycles
+}
+
+func reverseRenameEdges(edges []RenameEdge) {
+	for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+		edges[i], edges[j] = edges[j], edges[i]
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuperSchemaRenameHistory(t *testing.T) {
+	// reuse the package's existing multi-rename fixtures: tag 1 is "a" in
+	// sch1, "aa" in sch2, "aaa" in sch3.
+	ss, err := NewSuperSchema(sch1, sch2, sch3)
+	require.NoError(t, err)
+
+	history := ss.RenameHistory(1)
+	require.Len(t, history, 2)
+	assert.Equal(t, RenameEdge{From: "a", To: "aa", SourceSchemaID: 1}, history[0])
+	assert.Equal(t, RenameEdge{From: "aa", To: "aaa", SourceSchemaID: 2}, history[1])
+
+	assert.Equal(t, "a", ss.CanonicalName(1, 0))
+	assert.Equal(t, "aa", ss.CanonicalName(1, 1))
+	assert.Equal(t, "aaa", ss.CanonicalName(1, 2))
+
+	assert.Empty(t, ss.RenameHistory(3))
+	assert.Equal(t, "", ss.CanonicalName(99, 5))
+}
+
+func TestDetectRenameCycles(t *testing.T) {
+	t.Run("straight rename chain has no cycle", func(t *testing.T) {
+		edges := []RenameEdge{
+			{From: "a", To: "b", SourceSchemaID: 1},
+			{From: "b", To: "c", SourceSchemaID: 2},
+		}
+		assert.Empty(t, detectCyclesInTagGraph(edges))
+	})
+
+	t.Run("diamond that reconverges is not a cycle", func(t *testing.T) {
+		edges := []RenameEdge{
+			{From: "a", To: "b", SourceSchemaID: 1},
+			{From: "a", To: "c", SourceSchemaID: 2},
+			{From: "b", To: "d", SourceSchemaID: 3},
+			{From: "c", To: "d", SourceSchemaID: 4},
+		}
+		assert.Empty(t, detectCyclesInTagGraph(edges))
+	})
+
+	t.Run("explicit 3-node cycle is detected", func(t *testing.T) {
+		edges := []RenameEdge{
+			{From: "a", To: "b", SourceSchemaID: 1},
+			{From: "b", To: "c", SourceSchemaID: 2},
+			{From: "c", To: "a", SourceSchemaID: 3},
+		}
+		cycles := detectCyclesInTagGraph(edges)
+		require.Len(t, cycles, 1)
+		assert.Equal(t, edges, cycles[0])
+	})
+
+	t.Run("cycles are scoped per-tag, not global across tagNames", func(t *testing.T) {
+		ss := newEmptySuperSchema()
+		ss.renameGraph[1] = []RenameEdge{
+			{From: "a", To: "b", SourceSchemaID: 1},
+			{From: "b", To: "a", SourceSchemaID: 2},
+		}
+		ss.renameGraph[2] = []RenameEdge{
+			{From: "x", To: "b", SourceSchemaID: 1},
+		}
+
+		cycles := ss.DetectRenameCycles()
+		require.Len(t, cycles, 1)
+		assert.Equal(t, ss.renameGraph[1], cycles[0])
+	})
+}
+
+func TestGenerateSchemaFallsBackOnRenameCycle(t *testing.T) {
+	ss := newEmptySuperSchema()
+	ss.allCols.Cols = []Column{strCol("", 1, true)}
+	ss.allCols.TagToIdx[1] = 0
+	ss.tagNames[1] = []string{"a"}
+	ss.renameGraph[1] = []RenameEdge{
+		{From: "a", To: "b", SourceSchemaID: 1},
+		{From: "b", To: "a", SourceSchemaID: 2},
+	}
+
+	gs, err := ss.GenerateSchema()
+	require.NoError(t, err)
+
+	col, ok := gs.GetAllCols().GetByTag(1)
+	require.True(t, ok)
+	assert.Equal(t, "a_1", col.Name)
+}
@@ -0,0 +1,250 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// superSchemaSerialVersion is bumped whenever serialSuperSchema's shape
+// changes in a way that isn't backwards compatible.
+const superSchemaSerialVersion = 1
+
+// serialColumn is the on-disk form of a Column. TypeInfo is stored as its
+// Identifier rather than the concrete value so that decoding doesn't
+// depend on which TypeInfo implementation produced it.
+type serialColumn struct {
+	Tag         uint64
+	Kind        types.NomsKind
+	IsPartOfPK  bool
+	TypeInfo    typeinfo.Identifier
+	Constraints []string
+}
+
+// constraintNames returns the Name() of every constraint in cs, or nil if
+// cs is empty, so that a column with no constraints round-trips to nil
+// rather than an empty slice.
+func constraintNames(cs []ColConstraint) []string {
+	if len(cs) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = c.Name()
+	}
+
+	return names
+}
+
+func constraintsFromNames(names []string) ([]ColConstraint, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	cs := make([]ColConstraint, len(names))
+	for i, name := range names {
+		c, ok := ConstraintFromName(name)
+		if !ok {
+			return nil, fmt.Errorf("UnmarshalSuperSchema: unknown constraint %q", name)
+		}
+		cs[i] = c
+	}
+
+	return cs, nil
+}
+
+type serialSuperSchema struct {
+	Version     int
+	Cols        []serialColumn
+	TagNames    map[uint64][]string
+	Promotions  map[uint64][]typeinfo.Identifier
+	RenameGraph map[uint64][]RenameEdge
+}
+
+// MarshalNoms encodes ss, including its promotion and rename-lineage
+// metadata, as a types.Value suitable for storing alongside a commit.
+func (ss *SuperSchema) MarshalNoms() (types.Value, error) {
+	sss := serialSuperSchema{
+		Version:     superSchemaSerialVersion,
+		Cols:        make([]serialColumn, len(ss.allCols.Cols)),
+		TagNames:    ss.tagNames,
+		Promotions:  make(map[uint64][]typeinfo.Identifier, len(ss.promotions)),
+		RenameGraph: ss.renameGraph,
+	}
+
+	for i, col := range ss.allCols.Cols {
+		sss.Cols[i] = serialColumn{
+			Tag:         col.Tag,
+			Kind:        col.Kind,
+			IsPartOfPK:  col.IsPartOfPK,
+			TypeInfo:    col.TypeInfo.Identifier(),
+			Constraints: constraintNames(col.Constraints),
+		}
+	}
+
+	for tag, tis := range ss.promotions {
+		ids := make([]typeinfo.Identifier, len(tis))
+		for i, ti := range tis {
+			ids[i] = ti.Identifier()
+		}
+		sss.Promotions[tag] = ids
+	}
+
+	data, err := json.Marshal(sss)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.SerialMessage(data), nil
+}
+
+// UnmarshalSuperSchema decodes a types.Value produced by
+// (*SuperSchema).MarshalNoms back into a SuperSchema. GenerateSchema() on
+// the result is byte-for-byte reproducible: it generates the same Schema
+// the original SuperSchema would have.
+func UnmarshalSuperSchema(v types.Value) (*SuperSchema, error) {
+	sm, ok := v.(types.SerialMessage)
+	if !ok {
+		return nil, fmt.Errorf("UnmarshalSuperSchema: expected a types.SerialMessage, got %T", v)
+	}
+
+	var sss serialSuperSchema
+	if err := json.Unmarshal(sm, &sss); err != nil {
+		return nil, err
+	}
+
+	if sss.Version != superSchemaSerialVersion {
+		return nil, fmt.Errorf("UnmarshalSuperSchema: unsupported SuperSchema version %d", sss.Version)
+	}
+
+	ss := newEmptySuperSchema()
+
+	for _, sc := range sss.Cols {
+		ti, ok := typeinfo.FromIdentifier(sc.TypeInfo)
+		if !ok {
+			return nil, fmt.Errorf("UnmarshalSuperSchema: unknown TypeInfo identifier %q for tag %d", sc.TypeInfo, sc.Tag)
+		}
+
+		constraints, err := constraintsFromNames(sc.Constraints)
+		if err != nil {
+			return nil, err
+		}
+
+		col := Column{Tag: sc.Tag, Kind: sc.Kind, IsPartOfPK: sc.IsPartOfPK, TypeInfo: ti, Constraints: constraints}
+		ss.allCols.Cols = append(ss.allCols.Cols, col)
+		ss.allCols.TagToIdx[sc.Tag] = len(ss.allCols.Cols) - 1
+	}
+
+	ss.tagNames = sss.TagNames
+	ss.renameGraph = sss.RenameGraph
+
+	for tag, ids := range sss.Promotions {
+		tis := make([]typeinfo.TypeInfo, len(ids))
+		for i, id := range ids {
+			ti, ok := typeinfo.FromIdentifier(id)
+			if !ok {
+				return nil, fmt.Errorf("UnmarshalSuperSchema: unknown TypeInfo identifier %q in promotions for tag %d", id, tag)
+			}
+			tis[i] = ti
+		}
+		ss.promotions[tag] = tis
+	}
+
+	// nameTimeline is reconstructed on a best-effort basis from the rename
+	// graph: it is only used by CanonicalName, which is not part of the
+	// round-trip contract, but keeping it populated avoids a silently
+	// empty history for any tag that was in fact renamed. renameGraph
+	// entries are already in ascending SourceSchemaID order (the order
+	// renames were observed), which is the order CanonicalName requires.
+	// The schema index at which a tag was first introduced is not
+	// serialized, so each tag's oldest name is recorded as of schema 0: on
+	// the result, CanonicalName returns that name, not "", for schema
+	// indexes before the tag was introduced.
+	for tag, names := range ss.tagNames {
+		if len(names) == 0 {
+			continue
+		}
+		oldestName := names[len(names)-1]
+		ss.nameTimeline[tag] = []nameAtSchema{{schemaIdx: 0, name: oldestName}}
+		for _, edge := range ss.renameGraph[tag] {
+			ss.nameTimeline[tag] = append(ss.nameTimeline[tag], nameAtSchema{schemaIdx: int(edge.SourceSchemaID), name: edge.To})
+		}
+	}
+
+	return ss, nil
+}
+
+// TypeWideningEvent records that a tag's type was widened between two
+// SuperSchemas, as reported by SuperSchemaDiff.
+type TypeWideningEvent struct {
+	Tag  uint64
+	From typeinfo.TypeInfo
+	To   typeinfo.TypeInfo
+}
+
+// SuperSchemaDelta is the result of diffing two SuperSchemas.
+type SuperSchemaDelta struct {
+	// AddedTags are tags present in new but not old.
+	AddedTags []uint64
+	// RemovedTags are tags present in old but not new.
+	RemovedTags []uint64
+	// AddedAliases are, for tags present in both, the names new knows the
+	// tag by that old does not.
+	AddedAliases map[uint64][]string
+	// Widened are the tags whose type changed between old and new.
+	Widened []TypeWideningEvent
+}
+
+// SuperSchemaDiff compares old and new, reporting added and removed tags,
+// newly observed name aliases for tags present in both, and any type
+// widening that occurred between them.
+func SuperSchemaDiff(old, new *SuperSchema) SuperSchemaDelta {
+	delta := SuperSchemaDelta{AddedAliases: map[uint64][]string{}}
+
+	for _, col := range new.allCols.Cols {
+		if _, ok := old.allCols.GetByTag(col.Tag); !ok {
+			delta.AddedTags = append(delta.AddedTags, col.Tag)
+		}
+	}
+
+	for _, col := range old.allCols.Cols {
+		newCol, ok := new.allCols.GetByTag(col.Tag)
+		if !ok {
+			delta.RemovedTags = append(delta.RemovedTags, col.Tag)
+			continue
+		}
+
+		oldNames := map[string]bool{}
+		for _, n := range old.tagNames[col.Tag] {
+			oldNames[n] = true
+		}
+		for _, n := range new.tagNames[col.Tag] {
+			if !oldNames[n] {
+				delta.AddedAliases[col.Tag] = append(delta.AddedAliases[col.Tag], n)
+			}
+		}
+
+		if !typeInfoEquals(col.TypeInfo, newCol.TypeInfo) {
+			delta.Widened = append(delta.Widened, TypeWideningEvent{Tag: col.Tag, From: col.TypeInfo, To: newCol.TypeInfo})
+		}
+	}
+
+	return delta
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func TestSuperSchemaMarshalRoundTrip(t *testing.T) {
+	for _, test := range SuperSchemaTests {
+		if test.ExpectedErrString != "" {
+			continue
+		}
+
+		t.Run(test.Name, func(t *testing.T) {
+			ss, err := NewSuperSchema(test.Schemas...)
+			require.NoError(t, err)
+
+			v, err := ss.MarshalNoms()
+			require.NoError(t, err)
+
+			rtSS, err := UnmarshalSuperSchema(v)
+			require.NoError(t, err)
+
+			assert.True(t, ss.Equals(rtSS))
+
+			gs, err := rtSS.GenerateSchema()
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedGeneratedSchema, gs)
+
+			eq, err := SchemasAreEqual(test.ExpectedGeneratedSchema, gs)
+			require.NoError(t, err)
+			assert.True(t, eq)
+		})
+	}
+
+	t.Run("round trip preserves promotions and rename graph", func(t *testing.T) {
+		ss, err := NewSuperSchemaWithPromotion(
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int8Type)}),
+			mustSchema([]Column{numCol("y", 1, false, typeinfo.Int32Type)}),
+		)
+		require.NoError(t, err)
+
+		v, err := ss.MarshalNoms()
+		require.NoError(t, err)
+
+		rtSS, err := UnmarshalSuperSchema(v)
+		require.NoError(t, err)
+
+		assert.True(t, ss.Equals(rtSS))
+		assert.Equal(t, ss.promotions, rtSS.promotions)
+		assert.Equal(t, ss.RenameHistory(1), rtSS.RenameHistory(1))
+
+		gs, err := rtSS.GenerateSchema()
+		require.NoError(t, err)
+		col, ok := gs.GetAllCols().GetByTag(1)
+		require.True(t, ok)
+		assert.True(t, typeinfo.Int32Type.Equals(col.TypeInfo))
+	})
+
+	t.Run("round trip preserves CanonicalName for a tag with multiple renames", func(t *testing.T) {
+		ss, err := NewSuperSchema(
+			mustSchema([]Column{numCol("a", 1, false, typeinfo.Int32Type)}),
+			mustSchema([]Column{numCol("b", 1, false, typeinfo.Int32Type)}),
+			mustSchema([]Column{numCol("c", 1, false, typeinfo.Int32Type)}),
+		)
+		require.NoError(t, err)
+
+		v, err := ss.MarshalNoms()
+		require.NoError(t, err)
+
+		rtSS, err := UnmarshalSuperSchema(v)
+		require.NoError(t, err)
+
+		assert.Equal(t, "a", rtSS.CanonicalName(1, 0))
+		assert.Equal(t, "b", rtSS.CanonicalName(1, 1))
+		assert.Equal(t, "c", rtSS.CanonicalName(1, 2))
+	})
+
+	t.Run("round trip preserves column constraints", func(t *testing.T) {
+		ss, err := NewSuperSchema(mustSchema([]Column{
+			{"x", 1, types.IntKind, false, typeinfo.Int32Type, []ColConstraint{NotNullConstraint{}}},
+		}))
+		require.NoError(t, err)
+
+		v, err := ss.MarshalNoms()
+		require.NoError(t, err)
+
+		rtSS, err := UnmarshalSuperSchema(v)
+		require.NoError(t, err)
+
+		assert.True(t, ss.Equals(rtSS))
+
+		col, ok := rtSS.allCols.GetByTag(1)
+		require.True(t, ok)
+		assert.True(t, hasNotNull(col.Constraints))
+	})
+
+	t.Run("unmarshal rejects a non-SerialMessage value", func(t *testing.T) {
+		_, err := UnmarshalSuperSchema(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestSuperSchemaDiff(t *testing.T) {
+	oldSS, err := NewSuperSchema(sch1, sch2)
+	require.NoError(t, err)
+
+	newSS, err := NewSuperSchema(sch1, sch2, sch3)
+	require.NoError(t, err)
+
+	delta := SuperSchemaDiff(oldSS, newSS)
+	assert.ElementsMatch(t, []uint64{5}, delta.AddedTags)
+	assert.Empty(t, delta.RemovedTags)
+	assert.Equal(t, []string{"aaa"}, delta.AddedAliases[1])
+	assert.Equal(t, []string{"bbb"}, delta.AddedAliases[2])
+	assert.Empty(t, delta.Widened)
+
+	t.Run("removed tags are reported", func(t *testing.T) {
+		delta := SuperSchemaDiff(newSS, oldSS)
+		assert.ElementsMatch(t, []uint64{5}, delta.RemovedTags)
+		assert.Empty(t, delta.AddedTags)
+	})
+
+	t.Run("widening events are reported", func(t *testing.T) {
+		narrowSS, err := NewSuperSchemaWithPromotion(mustSchema([]Column{numCol("x", 1, false, typeinfo.Int8Type)}))
+		require.NoError(t, err)
+
+		wideSS, err := NewSuperSchemaWithPromotion(
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int8Type)}),
+			mustSchema([]Column{numCol("x", 1, false, typeinfo.Int32Type)}),
+		)
+		require.NoError(t, err)
+
+		delta := SuperSchemaDiff(narrowSS, wideSS)
+		require.Len(t, delta.Widened, 1)
+		assert.Equal(t, uint64(1), delta.Widened[0].Tag)
+		assert.True(t, typeinfo.Int8Type.Equals(delta.Widened[0].From))
+		assert.True(t, typeinfo.Int32Type.Equals(delta.Widened[0].To))
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package typeinfo describes the SQL-facing types that back each schema
+// Column, independent of the NomsKind used to store their values.
+package typeinfo
+
+import (
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// Identifier names a TypeInfo implementation so instances can be compared
+// and (de)serialized without relying on Go's reflect package.
+type Identifier string
+
+const (
+	Int8TypeIdentifier      Identifier = "int8"
+	Int16TypeIdentifier     Identifier = "int16"
+	Int32TypeIdentifier     Identifier = "int32"
+	Int64TypeIdentifier     Identifier = "int64"
+	Uint8TypeIdentifier     Identifier = "uint8"
+	Uint16TypeIdentifier    Identifier = "uint16"
+	Uint32TypeIdentifier    Identifier = "uint32"
+	Uint64TypeIdentifier    Identifier = "uint64"
+	Float32TypeIdentifier   Identifier = "float32"
+	Float64TypeIdentifier   Identifier = "float64"
+	DecimalTypeIdentifier   Identifier = "decimal"
+	StringTypeIdentifier    Identifier = "string"
+	BoolTypeIdentifier      Identifier = "bool"
+	BlobTypeIdentifier      Identifier = "blob"
+	TimestampTypeIdentifier Identifier = "timestamp"
+	DateTypeIdentifier      Identifier = "date"
+	TimeTypeIdentifier      Identifier = "time"
+)
+
+// TypeInfo is the interface implemented by every SQL-facing type that a
+// schema Column may carry. It is distinct from types.NomsKind, which only
+// describes how values of the type are physically encoded.
+type TypeInfo interface {
+	// Identifier returns the stable name of this TypeInfo.
+	Identifier() Identifier
+	// NomsKind returns the underlying storage kind for values of this type.
+	NomsKind() types.NomsKind
+	// Equals returns true if other describes the same type.
+	Equals(other TypeInfo) bool
+	// String returns the human readable name of the type, used when
+	// generating error messages and golden output.
+	String() string
+}
+
+type numericTypeInfo struct {
+	id   Identifier
+	kind types.NomsKind
+	name string
+}
+
+func (ti numericTypeInfo) Identifier() Identifier   { return ti.id }
+func (ti numericTypeInfo) NomsKind() types.NomsKind { return ti.kind }
+func (ti numericTypeInfo) String() string           { return ti.name }
+func (ti numericTypeInfo) Equals(other TypeInfo) bool {
+	if other == nil {
+		return false
+	}
+	return ti.id == other.Identifier()
+}
+
+var (
+	Int8Type        TypeInfo = numericTypeInfo{Int8TypeIdentifier, types.IntKind, "Int8"}
+	Int16Type       TypeInfo = numericTypeInfo{Int16TypeIdentifier, types.IntKind, "Int16"}
+	Int32Type       TypeInfo = numericTypeInfo{Int32TypeIdentifier, types.IntKind, "Int32"}
+	Int64Type       TypeInfo = numericTypeInfo{Int64TypeIdentifier, types.IntKind, "Int64"}
+	Uint8Type       TypeInfo = numericTypeInfo{Uint8TypeIdentifier, types.UintKind, "Uint8"}
+	Uint16Type      TypeInfo = numericTypeInfo{Uint16TypeIdentifier, types.UintKind, "Uint16"}
+	Uint32Type      TypeInfo = numericTypeInfo{Uint32TypeIdentifier, types.UintKind, "Uint32"}
+	Uint64Type      TypeInfo = numericTypeInfo{Uint64TypeIdentifier, types.UintKind, "Uint64"}
+	Float32Type     TypeInfo = numericTypeInfo{Float32TypeIdentifier, types.FloatKind, "Float32"}
+	Float64Type     TypeInfo = numericTypeInfo{Float64TypeIdentifier, types.FloatKind, "Float64"}
+	DecimalType     TypeInfo = numericTypeInfo{DecimalTypeIdentifier, types.DecimalKind, "Decimal"}
+	BoolDefaultType TypeInfo = numericTypeInfo{BoolTypeIdentifier, types.BoolKind, "Bool"}
+	BlobDefaultType TypeInfo = numericTypeInfo{BlobTypeIdentifier, types.BlobKind, "Blob"}
+
+	StringDefaultType TypeInfo = numericTypeInfo{StringTypeIdentifier, types.StringKind, "String"}
+	TimestampType     TypeInfo = numericTypeInfo{TimestampTypeIdentifier, types.TimestampKind, "Timestamp"}
+	DateType          TypeInfo = numericTypeInfo{DateTypeIdentifier, types.DateKind, "Date"}
+	TimeType          TypeInfo = numericTypeInfo{TimeTypeIdentifier, types.TimeKind, "Time"}
+)
+
+// FromIdentifier returns the canonical TypeInfo for a given Identifier, used
+// when decoding a TypeInfo that was serialized as its Identifier.
+func FromIdentifier(id Identifier) (TypeInfo, bool) {
+	for _, ti := range []TypeInfo{
+		Int8Type, Int16Type, Int32Type, Int64Type,
+		Uint8Type, Uint16Type, Uint32Type, Uint64Type,
+		Float32Type, Float64Type, DecimalType,
+		BoolDefaultType, BlobDefaultType, StringDefaultType,
+		TimestampType, DateType, TimeType,
+	} {
+		if ti.Identifier() == id {
+			return ti, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// NomsKind allows a TypeDesc to indicate what kind of type is described.
+type NomsKind uint8
+
+// These constants are used to serialize every type.
+const (
+	BoolKind NomsKind = iota
+	IntKind
+	UintKind
+	FloatKind
+	StringKind
+	BlobKind
+	DecimalKind
+	TimestampKind
+	DateKind
+	TimeKind
+	UUIDKind
+	UnknownKind
+)
+
+// Value is the interface that all Noms values implement.
+type Value interface {
+	Kind() NomsKind
+}
+
+// SerialMessage is a Value whose content is an opaque, already-encoded
+// message (e.g. a versioned flatbuffer or JSON payload) rather than a
+// native Noms value. It is used to round-trip types that have their own
+// serialization format through the store.
+type SerialMessage []byte
+
+// Kind implements Value. SerialMessage is always stored as a blob.
+func (sm SerialMessage) Kind() NomsKind {
+	return BlobKind
+}